@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command sidetree-mock runs a standalone Sidetree mock node for manual
+// testing and for driving client integration tests.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/Moopli/sidetree-mock/pkg/httpserver"
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/observer"
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
+)
+
+var logger = log.New("sidetree-mock")
+
+const (
+	url      = ":48326"
+	basePath = "/document"
+
+	didDocNamespace = "did:sidetree"
+	sha2_256        = 18
+)
+
+func main() {
+	strictJCS := flag.Bool("strict_jcs", false,
+		"reject create/update requests whose body is not RFC 8785 JCS canonical")
+	batchMode := flag.Bool("batch", false,
+		"defer operations through a batch ledger and observer instead of resolving them instantly; "+
+			"see POST "+basePath+"/admin/anchor and POST "+basePath+"/admin/rewind")
+	anchorInterval := flag.Duration("anchor_interval", 0,
+		"in batch mode, automatically anchor pending operations and apply them to the document store on "+
+			"this interval, in addition to on-demand anchoring via POST "+basePath+"/admin/anchor; "+
+			"0 disables automatic anchoring")
+	flag.Parse()
+
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:               "0.1",
+		GenesisBlockHeight: 0,
+		Protocol: coreprotocol.Protocol{
+			HashAlgorithmInMultiHashCode: sha2_256,
+			MaxOperationsPerBatch:        10,
+			MaxDeltaByteSize:             2000,
+		},
+	})
+
+	didDocHandler := mocks.NewMockDocumentHandler().
+		WithNamespace(didDocNamespace).
+		WithProtocolClient(registry)
+
+	ns := &httpserver.Namespace{
+		BasePath:  basePath,
+		Handler:   didDocHandler,
+		Registry:  registry,
+		LongForm:  true,
+		StrictJCS: *strictJCS,
+	}
+
+	if *batchMode {
+		batchLedger := ledger.New()
+		batchObserver := observer.New(batchLedger, registry, didDocNamespace, didDocHandler)
+		ns.BatchWriter = batchLedger
+		ns.Observer = batchObserver
+
+		if *anchorInterval > 0 {
+			go autoAnchor(batchLedger, batchObserver, *anchorInterval)
+		}
+	}
+
+	s := httpserver.New(url, "", "", ns)
+
+	if err := s.Start(); err != nil {
+		logger.Fatalf("failed to start sidetree-mock server: %s", err)
+	}
+
+	waitForInterrupt()
+
+	if err := s.Stop(context.Background()); err != nil {
+		logger.Errorf("failed to stop sidetree-mock server: %s", err)
+	}
+}
+
+func waitForInterrupt() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+}
+
+// autoAnchor periodically cuts a new transaction from whatever operations
+// are pending and immediately applies it, so that running in batch mode
+// with a non-zero anchor interval still eventually resolves documents
+// without any admin calls.
+func autoAnchor(l *ledger.Ledger, o *observer.Observer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := l.Anchor(); err != nil && err != ledger.ErrNothingPending {
+			logger.Errorf("failed to anchor pending operations: %s", err)
+			continue
+		}
+
+		if _, err := o.ProcessNext(); err != nil {
+			logger.Errorf("failed to process anchored transaction: %s", err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package dochandler contains resolver decorators that the mock node layers
+// on top of the handlers provided by sidetree-core-go.
+package dochandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/composer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const initialStateParts = 2
+
+// LongFormResolver decorates a resolver with support for long-form (a.k.a.
+// unpublished) DIDs. A long-form DID carries its own create payload, encoded
+// as a third colon-delimited segment appended to the short-form DID, e.g.
+// did:sidetree:<suffix>:<suffixData>.<delta>, so that the document can be
+// synthesized locally before the corresponding operation has been anchored
+// and observed. If the DID has already been anchored, the stored form always
+// takes precedence over the embedded initial state.
+type LongFormResolver struct {
+	resolver  dochandler.Resolver
+	namespace string
+	protocol  coreprotocol.Client
+}
+
+// NewLongFormResolver returns a LongFormResolver that falls back to resolver
+// for DIDs that have already been anchored. The hash algorithm used to
+// verify a long-form DID's suffix is looked up from activeProtocol on every
+// resolve, so it always reflects the protocol version currently active,
+// even across a rolling protocol upgrade.
+func NewLongFormResolver(activeProtocol coreprotocol.Client, resolver dochandler.Resolver) *LongFormResolver {
+	return &LongFormResolver{
+		resolver:  resolver,
+		namespace: resolver.Namespace(),
+		protocol:  activeProtocol,
+	}
+}
+
+// Namespace returns the namespace of the wrapped resolver.
+func (r *LongFormResolver) Namespace() string {
+	return r.namespace
+}
+
+// ResolveDocument resolves idOrDocument. If it carries an initial-state
+// segment, the stored (anchored) document takes precedence when present;
+// otherwise the document is synthesized from the initial state and returned
+// with methodMetadata.published set to false.
+func (r *LongFormResolver) ResolveDocument(idOrDocument string) (*document.ResolutionResult, error) {
+	did, initialState, err := getParts(r.namespace, idOrDocument)
+	if err != nil {
+		return nil, fmt.Errorf("bad request: %w", err)
+	}
+
+	if initialState == nil {
+		return r.resolver.ResolveDocument(idOrDocument)
+	}
+
+	if result, err := r.resolver.ResolveDocument(did); err == nil {
+		return result, nil
+	}
+
+	return r.resolveInitialState(did, initialState)
+}
+
+func (r *LongFormResolver) resolveInitialState(did string, initialState *model.CreateRequest) (*document.ResolutionResult, error) {
+	multihashCode := r.protocol.Current().HashAlgorithmInMultiHashCode
+
+	recalculatedDID, err := docutil.CalculateID(r.namespace, initialState.SuffixData, multihashCode)
+	if err != nil {
+		return nil, fmt.Errorf("bad request: calculate id from initial state: %w", err)
+	}
+
+	if recalculatedDID != did {
+		return nil, fmt.Errorf("bad request: initial state does not match suffix in DID %s", did)
+	}
+
+	decodedDelta, err := docutil.DecodeString(initialState.Delta)
+	if err != nil {
+		return nil, fmt.Errorf("bad request: decode delta from initial state: %w", err)
+	}
+
+	var delta model.DeltaModel
+	if err := json.Unmarshal(decodedDelta, &delta); err != nil {
+		return nil, fmt.Errorf("bad request: unmarshal delta from initial state: %w", err)
+	}
+
+	doc, err := composer.ApplyPatches(make(document.Document), delta.Patches)
+	if err != nil {
+		return nil, fmt.Errorf("bad request: apply patches from initial state: %w", err)
+	}
+
+	doc[document.IDProperty] = did
+
+	return &document.ResolutionResult{
+		Document: doc,
+		MethodMetadata: document.MethodMetadata{
+			Published: false,
+		},
+	}, nil
+}
+
+// getParts splits idOrDocument into the short-form DID (<namespace>:<suffix>)
+// and, if present, the initial-state create payload carried as a third
+// colon-delimited segment: <namespace>:<suffix>:<suffixData>.<delta>.
+func getParts(namespace, idOrDocument string) (string, *model.CreateRequest, error) {
+	rest := strings.TrimPrefix(idOrDocument, namespace+docutil.NamespaceDelimiter)
+	if rest == idOrDocument {
+		return idOrDocument, nil, nil
+	}
+
+	segments := strings.SplitN(rest, docutil.NamespaceDelimiter, 2)
+	if len(segments) < initialStateParts {
+		return idOrDocument, nil, nil
+	}
+
+	did := namespace + docutil.NamespaceDelimiter + segments[0]
+	initialStateValue := segments[1]
+
+	if initialStateValue == "" {
+		return "", nil, fmt.Errorf("initial state is present but empty")
+	}
+
+	parts := strings.Split(initialStateValue, ".")
+	if len(parts) != initialStateParts {
+		return "", nil, fmt.Errorf("initial state should have two parts: suffix data and delta")
+	}
+
+	return did, &model.CreateRequest{
+		Operation:  model.OperationTypeCreate,
+		SuffixData: parts[0],
+		Delta:      parts[1],
+	}, nil
+}
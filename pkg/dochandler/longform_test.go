@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
+)
+
+const (
+	namespace     = "did:sidetree"
+	multihashCode = 18
+)
+
+// testRegistry returns a protocol.Registry (a coreprotocol.Client) fixed at
+// multihashCode, for tests that don't care about protocol upgrades.
+func testRegistry() *protocol.Registry {
+	return protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: multihashCode},
+	})
+}
+
+type mockResolver struct {
+	namespace string
+	result    *document.ResolutionResult
+	err       error
+}
+
+func (m *mockResolver) Namespace() string {
+	return m.namespace
+}
+
+func (m *mockResolver) ResolveDocument(string) (*document.ResolutionResult, error) {
+	return m.result, m.err
+}
+
+func TestLongFormResolver_AnchoredTakesPrecedence(t *testing.T) {
+	anchored := &document.ResolutionResult{Document: document.Document{"id": "anchored"}}
+	r := NewLongFormResolver(testRegistry(), &mockResolver{namespace: namespace, result: anchored})
+
+	did, initialState := createLongFormDID(t)
+
+	result, err := r.ResolveDocument(did + initialState)
+	require.NoError(t, err)
+	require.Equal(t, anchored, result)
+}
+
+func TestLongFormResolver_SynthesizesUnpublishedDocument(t *testing.T) {
+	r := NewLongFormResolver(testRegistry(), &mockResolver{namespace: namespace, err: errors.New("not found")})
+
+	did, initialState := createLongFormDID(t)
+
+	result, err := r.ResolveDocument(did + initialState)
+	require.NoError(t, err)
+	require.Equal(t, did, result.Document["id"])
+	require.False(t, result.MethodMetadata.Published)
+}
+
+func TestLongFormResolver_NoInitialState(t *testing.T) {
+	anchored := &document.ResolutionResult{Document: document.Document{"id": "anchored"}}
+	r := NewLongFormResolver(testRegistry(), &mockResolver{namespace: namespace, result: anchored})
+
+	result, err := r.ResolveDocument(namespace + ":suffix")
+	require.NoError(t, err)
+	require.Equal(t, anchored, result)
+}
+
+func TestLongFormResolver_SuffixMismatch(t *testing.T) {
+	r := NewLongFormResolver(testRegistry(), &mockResolver{namespace: namespace, err: errors.New("not found")})
+
+	_, initialState := createLongFormDID(t)
+
+	_, err := r.ResolveDocument(namespace + ":wrong-suffix" + initialState)
+	require.Error(t, err)
+}
+
+// TestLongFormResolver_UsesCurrentProtocolAtResolveTime verifies that suffix
+// verification in resolveInitialState looks up the hash algorithm from the
+// registry at resolve time rather than capturing a snapshot when the
+// LongFormResolver was constructed: moving the registry's block height
+// forward after construction, to a version with a different hash algorithm,
+// must be reflected immediately on the next resolve.
+func TestLongFormResolver_UsesCurrentProtocolAtResolveTime(t *testing.T) {
+	const sha2_512 = 19
+
+	registry := protocol.NewRegistry(
+		protocol.Version{Name: "0.1", GenesisBlockHeight: 0, Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: multihashCode}},
+		protocol.Version{Name: "0.2", GenesisBlockHeight: 1, Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_512}},
+	)
+	r := NewLongFormResolver(registry, &mockResolver{namespace: namespace, err: errors.New("not found")})
+
+	did, initialState := createLongFormDID(t)
+
+	registry.SetBlockHeight(1)
+
+	_, err := r.ResolveDocument(did + initialState)
+	require.Error(t, err,
+		"suffix verification must use the protocol version active at resolve time, not one captured at construction")
+}
+
+// createLongFormDID builds a long-form DID (short-form DID plus its
+// initial-state segment) the same way a client would, using
+// helper.NewCreateRequest to produce the suffix_data/delta pair.
+func createLongFormDID(t *testing.T) (did, initialState string) {
+	req, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument: `{"publicKey":[{"id":"key-1","type":"JwsVerificationKey2020","usage":["ops","general"],"jwk":{"kty":"EC","crv":"P-256K","x":"PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA","y":"nM84jDHCMOTGTh_ZdHq4dBBdo4Z5PkEOW9jA8z8IsGc"}}]}`,
+		RecoveryKey:    &jws.JWK{Kty: "EC", Crv: "P-256K", X: "PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA"},
+		MultihashCode:  multihashCode,
+	})
+	require.NoError(t, err)
+
+	var createReq struct {
+		SuffixData string `json:"suffix_data"`
+		Delta      string `json:"delta"`
+	}
+	require.NoError(t, json.Unmarshal(req, &createReq))
+
+	did, err = docutil.CalculateID(namespace, createReq.SuffixData, multihashCode)
+	require.NoError(t, err)
+
+	initialState = docutil.NamespaceDelimiter + createReq.SuffixData + "." + createReq.Delta
+
+	return did, initialState
+}
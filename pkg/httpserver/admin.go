@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/observer"
+)
+
+// pendingOperation describes a queued, not-yet-anchored operation, as
+// reported by adminOperationsHandler.
+type pendingOperation struct {
+	UniqueSuffix string `json:"uniqueSuffix"`
+	Type         string `json:"type,omitempty"`
+}
+
+// adminOperationsHandler reports the operations queued for the next batch.
+type adminOperationsHandler struct {
+	path   string
+	ledger *ledger.Ledger
+}
+
+func (h *adminOperationsHandler) Path() string   { return h.path }
+func (h *adminOperationsHandler) Method() string { return http.MethodGet }
+
+func (h *adminOperationsHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		pending := h.ledger.Pending()
+
+		operations := make([]pendingOperation, len(pending))
+		for i, op := range pending {
+			var schema struct {
+				Type string `json:"type"`
+			}
+
+			if err := json.Unmarshal(op.Data, &schema); err != nil {
+				logger.Warnf("pending operation %q has malformed data: %s", op.UniqueSuffix, err)
+			}
+
+			operations[i] = pendingOperation{UniqueSuffix: op.UniqueSuffix, Type: schema.Type}
+		}
+
+		common.WriteResponse(rw, http.StatusOK, operations)
+	}
+}
+
+// adminAnchorHandler cuts the current pending pool into a new anchored
+// transaction on demand.
+type adminAnchorHandler struct {
+	path   string
+	ledger *ledger.Ledger
+}
+
+func (h *adminAnchorHandler) Path() string   { return h.path }
+func (h *adminAnchorHandler) Method() string { return http.MethodPost }
+
+func (h *adminAnchorHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		txn, err := h.ledger.Anchor()
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, err)
+			return
+		}
+
+		common.WriteResponse(rw, http.StatusOK, txn)
+	}
+}
+
+// adminRewindHandler discards every transaction anchored after the given
+// height, simulating a chain reorganization.
+type adminRewindHandler struct {
+	path   string
+	ledger *ledger.Ledger
+}
+
+func (h *adminRewindHandler) Path() string   { return h.path }
+func (h *adminRewindHandler) Method() string { return http.MethodPost }
+
+func (h *adminRewindHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		raw := req.URL.Query().Get("height")
+
+		height, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, fmt.Errorf("invalid height %q: %w", raw, err))
+			return
+		}
+
+		if err := h.ledger.Rewind(height); err != nil {
+			common.WriteError(rw, http.StatusBadRequest, err)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	}
+}
+
+// adminObserveHandler response reports how many operations an
+// adminObserveHandler call applied.
+type adminObserveResponse struct {
+	Applied int `json:"applied"`
+}
+
+// adminObserveHandler drives the namespace's Observer forward on demand,
+// applying its next unprocessed transaction's operations.
+type adminObserveHandler struct {
+	path     string
+	observer *observer.Observer
+}
+
+func (h *adminObserveHandler) Path() string   { return h.path }
+func (h *adminObserveHandler) Method() string { return http.MethodPost }
+
+func (h *adminObserveHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		applied, err := h.observer.ProcessNext()
+		if err != nil {
+			common.WriteError(rw, http.StatusInternalServerError, err)
+			return
+		}
+
+		common.WriteResponse(rw, http.StatusOK, adminObserveResponse{Applied: applied})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
+)
+
+// batchUpdateHandler handles the creation and update of documents by
+// queueing the parsed operation onto a Ledger instead of applying it to the
+// document store immediately. The operation only takes effect once an
+// Observer processes the transaction it is anchored in.
+type batchUpdateHandler struct {
+	basePath  string
+	namespace string
+	registry  *protocol.Registry
+	ledger    *ledger.Ledger
+}
+
+func (h *batchUpdateHandler) Path() string   { return h.basePath + "/operations" }
+func (h *batchUpdateHandler) Method() string { return http.MethodPost }
+
+func (h *batchUpdateHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		request, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, err)
+			return
+		}
+
+		op, err := h.parseOperation(request)
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, err)
+			return
+		}
+
+		h.ledger.Add(&batch.OperationInfo{Data: request, UniqueSuffix: op.UniqueSuffix})
+
+		rw.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *batchUpdateHandler) parseOperation(request []byte) (*batch.Operation, error) {
+	var schema struct {
+		Operation model.OperationType `json:"type"`
+	}
+	if err := json.Unmarshal(request, &schema); err != nil {
+		return nil, err
+	}
+
+	protocol := h.registry.Current()
+
+	var op *batch.Operation
+	var err error
+
+	switch schema.Operation {
+	case model.OperationTypeCreate:
+		op, err = operation.ParseCreateOperation(request, protocol)
+	case model.OperationTypeUpdate:
+		op, err = operation.ParseUpdateOperation(request, protocol)
+	case model.OperationTypeDeactivate:
+		op, err = operation.ParseDeactivateOperation(request, protocol)
+	case model.OperationTypeRecover:
+		op, err = operation.ParseRecoverOperation(request, protocol)
+	default:
+		return nil, fmt.Errorf("operation type [%s] not implemented", schema.Operation)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	op.ID = h.namespace + docutil.NamespaceDelimiter + op.UniqueSuffix
+
+	return op, nil
+}
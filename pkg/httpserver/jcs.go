@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/Moopli/sidetree-mock/pkg/jcs"
+)
+
+// jcsEnforcingHandler wraps any HTTPHandler, rejecting any request whose
+// body is not already in RFC 8785 JCS canonical form before it reaches the
+// wrapped handler's own request handler.
+type jcsEnforcingHandler struct {
+	common.HTTPHandler
+}
+
+// Handler returns the canonicalization-enforcing request handler.
+func (h *jcsEnforcingHandler) Handler() common.HTTPRequestHandler {
+	return enforceJCS(h.HTTPHandler.Handler())
+}
+
+// enforceJCS wraps next, rejecting the request with 400 if its body is not
+// valid JSON in RFC 8785 JCS canonical form, and otherwise passing the
+// (re-readable) body through unchanged.
+func enforceJCS(next common.HTTPRequestHandler) common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, err)
+			return
+		}
+
+		canonical, err := jcs.Transform(body)
+		if err != nil {
+			common.WriteError(rw, http.StatusBadRequest, fmt.Errorf("invalid JSON: %w", err))
+			return
+		}
+
+		if !bytes.Equal(bytes.TrimSpace(body), canonical) {
+			common.WriteError(rw, http.StatusBadRequest, errors.New("request body is not JCS canonical"))
+			return
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next(rw, req)
+	}
+}
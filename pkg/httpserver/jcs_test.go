@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceJCS(t *testing.T) {
+	var called bool
+
+	next := func(rw http.ResponseWriter, req *http.Request) {
+		called = true
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	t.Run("passes through a canonical body", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"a":1,"b":2}`)))
+
+		rw := httptest.NewRecorder()
+		enforceJCS(next)(rw, req)
+
+		require.True(t, called)
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
+
+	t.Run("rejects a non-canonical body", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"b":2,"a":1}`)))
+
+		rw := httptest.NewRecorder()
+		enforceJCS(next)(rw, req)
+
+		require.False(t, called)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "not JCS canonical")
+	})
+
+	t.Run("rejects invalid JSON", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`not json`)))
+
+		rw := httptest.NewRecorder()
+		enforceJCS(next)(rw, req)
+
+		require.False(t, called)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+}
@@ -0,0 +1,13 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+var logger = log.New("sidetree-mock-httpserver")
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	coredochandler "github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/observer"
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
+)
+
+// DocumentHandler is satisfied by a Sidetree document handler that can both
+// process create/update/recover/deactivate operations and resolve
+// documents, e.g. *mocks.MockDocumentHandler.
+type DocumentHandler interface {
+	coredochandler.Processor
+	coredochandler.Resolver
+}
+
+// Namespace describes a Sidetree DID namespace served by the node: the
+// document handler backing it, the base HTTP path its operations and
+// resolution endpoints are mounted under, and the ordered set of protocol
+// versions that have applied to it over the namespace's lifetime.
+//
+// Handler.Protocol() is expected to return Registry, so that operation
+// processing (which consults the current protocol version to select the
+// parser/client for it) and the /version, /versions endpoints below never
+// disagree about which version is active.
+type Namespace struct {
+	BasePath string
+	Handler  DocumentHandler
+	Registry *protocol.Registry
+
+	// LongForm enables long-form (unpublished) DID resolution for this
+	// namespace; see dochandler.LongFormResolver.
+	LongForm bool
+
+	// StrictJCS rejects create/update requests whose body is not already
+	// serialized in RFC 8785 JCS canonical form; see jcs.Transform.
+	StrictJCS bool
+
+	// BatchWriter, if set, defers operations submitted to this namespace:
+	// instead of being applied to Handler immediately, they are queued on
+	// BatchWriter and only take effect once Observer processes the
+	// transaction they are anchored in. Admin endpoints are mounted under
+	// BasePath to cut batches, inspect the pending pool, and rewind the
+	// chain. BatchWriter and Observer must either both be set or both be
+	// nil, and Observer must be driven by the same BatchWriter.
+	BatchWriter *ledger.Ledger
+
+	// Observer, if set, is driven on demand via POST {BasePath}/admin/observe,
+	// in addition to any direct ProcessNext calls a test makes against it.
+	Observer *observer.Observer
+}
+
+// versionHandler serves the active and historical protocol parameters for a
+// namespace as JSON.
+type versionHandler struct {
+	path     string
+	registry *protocol.Registry
+	history  bool
+}
+
+func (h *versionHandler) Path() string   { return h.path }
+func (h *versionHandler) Method() string { return http.MethodGet }
+
+func (h *versionHandler) Handler() common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		if h.history {
+			common.WriteResponse(rw, http.StatusOK, h.registry.Versions())
+			return
+		}
+
+		common.WriteResponse(rw, http.StatusOK, h.registry.CurrentVersion())
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
+	coredochandler "github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+
+	"github.com/Moopli/sidetree-mock/pkg/dochandler"
+	"github.com/Moopli/sidetree-mock/pkg/requestid"
+)
+
+// Server implements an HTTP(S) server that dispatches to a set of Sidetree
+// REST handlers.
+type Server struct {
+	httpServer *http.Server
+	certFile   string
+	keyFile    string
+	started    bool
+}
+
+// New returns a new Server that serves the given namespaces on url. For each
+// namespace it mounts the standard Sidetree create/update/recover/deactivate
+// and resolution endpoints under BasePath, plus GET {BasePath}/version and
+// GET {BasePath}/versions, which report the protocol parameters active at
+// the namespace's current mock block height and the full version history,
+// respectively. If certFile and keyFile are both non-empty, the server is
+// started over TLS.
+func New(url, certFile, keyFile string, namespaces ...*Namespace) *Server {
+	router := mux.NewRouter()
+
+	for _, ns := range namespaces {
+		for _, handler := range namespaceHandlers(ns) {
+			router.HandleFunc(handler.Path(), logRequest(handler.Handler())).Methods(handler.Method())
+		}
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:    url,
+			Handler: requestid.Middleware(router),
+		},
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+}
+
+func namespaceHandlers(ns *Namespace) []common.HTTPHandler {
+	var resolver coredochandler.Resolver = ns.Handler
+	if ns.LongForm {
+		resolver = dochandler.NewLongFormResolver(ns.Registry, ns.Handler)
+	}
+
+	var updateHandler common.HTTPHandler = diddochandler.NewUpdateHandler(ns.BasePath, ns.Handler)
+	if ns.BatchWriter != nil {
+		updateHandler = &batchUpdateHandler{
+			basePath:  ns.BasePath,
+			namespace: ns.Handler.Namespace(),
+			registry:  ns.Registry,
+			ledger:    ns.BatchWriter,
+		}
+	}
+
+	if ns.StrictJCS {
+		updateHandler = &jcsEnforcingHandler{HTTPHandler: updateHandler}
+	}
+
+	handlers := []common.HTTPHandler{
+		updateHandler,
+		diddochandler.NewResolveHandler(ns.BasePath, resolver),
+		&versionHandler{path: fmt.Sprintf("%s/version", ns.BasePath), registry: ns.Registry},
+		&versionHandler{path: fmt.Sprintf("%s/versions", ns.BasePath), registry: ns.Registry, history: true},
+	}
+
+	if ns.BatchWriter != nil {
+		handlers = append(handlers,
+			&adminOperationsHandler{path: fmt.Sprintf("%s/admin/operations", ns.BasePath), ledger: ns.BatchWriter},
+			&adminAnchorHandler{path: fmt.Sprintf("%s/admin/anchor", ns.BasePath), ledger: ns.BatchWriter},
+			&adminRewindHandler{path: fmt.Sprintf("%s/admin/rewind", ns.BasePath), ledger: ns.BatchWriter},
+		)
+	}
+
+	if ns.Observer != nil {
+		handlers = append(handlers,
+			&adminObserveHandler{path: fmt.Sprintf("%s/admin/observe", ns.BasePath), observer: ns.Observer},
+		)
+	}
+
+	return handlers
+}
+
+// logRequest wraps handler, logging the request's method, path,
+// X-Request-ID (propagated by requestid.Middleware) and response status once
+// handler has completed, so a failure can be correlated between the client's
+// and the server's logs by requestID even though the failure itself is
+// logged deep inside the vendored dochandler package, which has no access to
+// the request's context.
+func logRequest(handler common.HTTPRequestHandler) common.HTTPRequestHandler {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		requestID := requestid.FromContext(req.Context())
+		wrapped := &statusCapturingWriter{ResponseWriter: rw, status: http.StatusOK}
+
+		handler(wrapped, req)
+
+		logger.Debugf("handled request [method=%s, path=%s, requestID=%s, status=%d]",
+			req.Method, req.URL.Path, requestID, wrapped.status)
+	}
+}
+
+// statusCapturingWriter records the status code passed to WriteHeader so it
+// can be logged after the wrapped handler returns.
+type statusCapturingWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusCapturingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Start starts the server in a separate goroutine. It returns an error if the
+// server has already been started.
+func (s *Server) Start() error {
+	if s.started {
+		return errors.New("server already started")
+	}
+
+	go func() {
+		var err error
+		if s.certFile != "" && s.keyFile != "" {
+			err = s.httpServer.ListenAndServeTLS(s.certFile, s.keyFile)
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorf("HTTP server closed unexpectedly: %s", err)
+		}
+	}()
+
+	s.started = true
+
+	return nil
+}
+
+// Stop stops the server, gracefully shutting down any active connections. It
+// returns an error if the server was never started or has already been
+// stopped.
+func (s *Server) Stop(ctx context.Context) error {
+	if !s.started {
+		return errors.New("server not started")
+	}
+
+	s.started = false
+
+	return s.httpServer.Shutdown(ctx)
+}
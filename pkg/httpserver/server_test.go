@@ -18,15 +18,17 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
-	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
-	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
-	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/observer"
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
 )
 
 const (
@@ -39,19 +41,38 @@ const (
 	sha2_256        = 18
 	sampleNamespace = "sample:sidetree"
 	samplePath      = "/sample"
+
+	batchURL       = "localhost:8081"
+	batchClientURL = "http://" + batchURL
+	batchNamespace = "batch:sidetree"
+	batchPath      = "/batch"
+
+	jcsURL       = "localhost:8082"
+	jcsClientURL = "http://" + jcsURL
+	jcsNamespace = "jcs:sidetree"
+	jcsPath      = "/jcs"
 )
 
 func TestServer_Start(t *testing.T) {
-	didDocHandler := mocks.NewMockDocumentHandler().WithNamespace(didDocNamespace)
-	sampleDocHandler := mocks.NewMockDocumentHandler().WithNamespace(sampleNamespace)
+	didDocRegistry := protocol.NewRegistry(protocol.Version{
+		Name:               "0.1",
+		GenesisBlockHeight: 0,
+		Protocol:           coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sampleRegistry := protocol.NewRegistry(protocol.Version{
+		Name:               "0.1",
+		GenesisBlockHeight: 0,
+		Protocol:           coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+
+	didDocHandler := mocks.NewMockDocumentHandler().WithNamespace(didDocNamespace).WithProtocolClient(didDocRegistry)
+	sampleDocHandler := mocks.NewMockDocumentHandler().WithNamespace(sampleNamespace).WithProtocolClient(sampleRegistry)
 
 	s := New(url,
 		"",
 		"",
-		diddochandler.NewUpdateHandler(basePath, didDocHandler),
-		diddochandler.NewResolveHandler(basePath, didDocHandler),
-		newSampleUpdateHandler(sampleDocHandler),
-		newSampleResolveHandler(sampleDocHandler),
+		&Namespace{BasePath: basePath, Handler: didDocHandler, Registry: didDocRegistry, LongForm: true},
+		&Namespace{BasePath: samplePath, Handler: sampleDocHandler, Registry: sampleRegistry},
 	)
 	require.NoError(t, s.Start())
 	require.Error(t, s.Start())
@@ -73,7 +94,7 @@ func TestServer_Start(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("Create DID doc", func(t *testing.T) {
-		resp, err := httpPut(t, clientURL+basePath, req)
+		resp, err := httpPut(t, clientURL+basePath+"/operations", req)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 
@@ -82,7 +103,7 @@ func TestServer_Start(t *testing.T) {
 		require.Equal(t, didID, result.Document["id"])
 	})
 	t.Run("Resolve DID doc", func(t *testing.T) {
-		resp, err := httpGet(t, clientURL+basePath+"/"+didID)
+		resp, err := httpGet(t, clientURL+basePath+"/identifiers/"+didID)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 
@@ -91,7 +112,7 @@ func TestServer_Start(t *testing.T) {
 		require.Equal(t, didID, result.Document["id"])
 	})
 	t.Run("Create Sample doc", func(t *testing.T) {
-		resp, err := httpPut(t, clientURL+samplePath, req)
+		resp, err := httpPut(t, clientURL+samplePath+"/operations", req)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 
@@ -100,7 +121,7 @@ func TestServer_Start(t *testing.T) {
 		require.Equal(t, sampleID, result.Document["id"])
 	})
 	t.Run("Resolve Sample doc", func(t *testing.T) {
-		resp, err := httpGet(t, clientURL+samplePath+"/"+sampleID)
+		resp, err := httpGet(t, clientURL+samplePath+"/identifiers/"+sampleID)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 
@@ -108,12 +129,168 @@ func TestServer_Start(t *testing.T) {
 		require.NoError(t, json.Unmarshal(resp, &result))
 		require.Equal(t, sampleID, result.Document["id"])
 	})
+	t.Run("Get current version", func(t *testing.T) {
+		resp, err := httpGet(t, clientURL+basePath+"/version")
+		require.NoError(t, err)
+
+		var v protocol.Version
+		require.NoError(t, json.Unmarshal(resp, &v))
+		require.Equal(t, "0.1", v.Name)
+	})
+	t.Run("Get version history", func(t *testing.T) {
+		resp, err := httpGet(t, clientURL+basePath+"/versions")
+		require.NoError(t, err)
+
+		var versions []protocol.Version
+		require.NoError(t, json.Unmarshal(resp, &versions))
+		require.Len(t, versions, 1)
+	})
 	t.Run("Stop", func(t *testing.T) {
 		require.NoError(t, s.Stop(context.Background()))
 		require.Error(t, s.Stop(context.Background()))
 	})
 }
 
+func TestServer_BatchNamespace(t *testing.T) {
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:               "0.1",
+		GenesisBlockHeight: 0,
+		Protocol:           coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(batchNamespace).WithProtocolClient(registry)
+
+	batchLedger := ledger.New()
+	batchObserver := observer.New(batchLedger, registry, batchNamespace, docHandler)
+
+	s := New(batchURL, "", "",
+		&Namespace{
+			BasePath:    batchPath,
+			Handler:     docHandler,
+			Registry:    registry,
+			BatchWriter: batchLedger,
+			Observer:    batchObserver,
+		},
+	)
+	require.NoError(t, s.Start())
+	defer func() { require.NoError(t, s.Stop(context.Background())) }()
+
+	time.Sleep(time.Second)
+
+	req, err := getCreateRequest()
+	require.NoError(t, err)
+
+	var createReq model.CreateRequest
+	require.NoError(t, json.Unmarshal(req, &createReq))
+
+	did, err := docutil.CalculateID(batchNamespace, createReq.SuffixData, sha2_256)
+	require.NoError(t, err)
+
+	t.Run("Queue create operation", func(t *testing.T) {
+		status, _ := httpPost(t, batchClientURL+batchPath+"/operations", req)
+		require.Equal(t, http.StatusAccepted, status)
+	})
+
+	t.Run("Not yet resolvable before anchoring", func(t *testing.T) {
+		_, err := httpGet(t, batchClientURL+batchPath+"/identifiers/"+did)
+		require.Error(t, err)
+	})
+
+	t.Run("Pending pool shows the queued operation", func(t *testing.T) {
+		resp, err := httpGet(t, batchClientURL+batchPath+"/admin/operations")
+		require.NoError(t, err)
+
+		var pending []map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp, &pending))
+		require.Len(t, pending, 1)
+		require.Equal(t, "create", pending[0]["type"])
+	})
+
+	t.Run("Anchor cuts a new transaction", func(t *testing.T) {
+		status, resp := httpPost(t, batchClientURL+batchPath+"/admin/anchor", nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, string(resp), `"height":1`)
+	})
+
+	t.Run("Still not resolvable until the observer processes it", func(t *testing.T) {
+		_, err := httpGet(t, batchClientURL+batchPath+"/identifiers/"+did)
+		require.Error(t, err)
+	})
+
+	t.Run("Resolvable after POST /admin/observe", func(t *testing.T) {
+		status, observeResp := httpPost(t, batchClientURL+batchPath+"/admin/observe", nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, string(observeResp), `"applied":1`)
+
+		resp, err := httpGet(t, batchClientURL+batchPath+"/identifiers/"+did)
+		require.NoError(t, err)
+
+		var result document.ResolutionResult
+		require.NoError(t, json.Unmarshal(resp, &result))
+		require.Equal(t, did, result.Document["id"])
+	})
+
+	t.Run("Rewind discards the anchored transaction", func(t *testing.T) {
+		status, _ := httpPost(t, batchClientURL+batchPath+"/admin/rewind?height=0", nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, uint64(0), batchLedger.Height())
+	})
+}
+
+func TestServer_StrictJCS(t *testing.T) {
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:               "0.1",
+		GenesisBlockHeight: 0,
+		Protocol:           coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(jcsNamespace).WithProtocolClient(registry)
+
+	s := New(jcsURL, "", "",
+		&Namespace{BasePath: jcsPath, Handler: docHandler, Registry: registry, StrictJCS: true},
+	)
+	require.NoError(t, s.Start())
+	defer func() { require.NoError(t, s.Stop(context.Background())) }()
+
+	time.Sleep(time.Second)
+
+	t.Run("rejects a non-canonical request body", func(t *testing.T) {
+		status, resp := httpPost(t, jcsClientURL+jcsPath+"/operations", []byte(`{"b":2,"a":1}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(resp), "not JCS canonical")
+	})
+
+	t.Run("accepts a canonical create request", func(t *testing.T) {
+		req, err := getCreateRequest()
+		require.NoError(t, err)
+
+		status, _ := httpPost(t, jcsClientURL+jcsPath+"/operations", req)
+		require.Equal(t, http.StatusOK, status)
+	})
+}
+
+// httpPost sends a POST request to url with the given body and returns the
+// response status code and body without treating a non-200 status as an
+// error, unlike httpPut.
+func httpPost(t *testing.T, url string, body []byte) (int, []byte) {
+	client := &http.Client{}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := invokeWithRetry(
+		func() (response *http.Response, e error) {
+			return client.Do(httpReq)
+		},
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, respBody
+}
+
 // httpPut sends a regular POST request to the sidetree-node
 // - If post request has operation "create" then return sidetree document else no response
 func httpPut(t *testing.T, url string, req []byte) ([]byte, error) {
@@ -171,65 +348,10 @@ func invokeWithRetry(invoke func() (*http.Response, error)) (*http.Response, err
 	}
 }
 
-type sampleUpdateHandler struct {
-	*dochandler.UpdateHandler
-}
-
-func newSampleUpdateHandler(processor dochandler.Processor) *sampleUpdateHandler {
-	return &sampleUpdateHandler{
-		UpdateHandler: dochandler.NewUpdateHandler(processor),
-	}
-}
-
-// Path returns the context path
-func (h *sampleUpdateHandler) Path() string {
-	return samplePath
-}
-
-// Method returns the HTTP method
-func (h *sampleUpdateHandler) Method() string {
-	return http.MethodPost
-}
-
-// Handler returns the handler
-func (h *sampleUpdateHandler) Handler() common.HTTPRequestHandler {
-	return h.Update
-}
-
-// Update creates/updates the document
-func (o *sampleUpdateHandler) Update(rw http.ResponseWriter, req *http.Request) {
-	o.UpdateHandler.Update(rw, req)
-}
-
-type sampleResolveHandler struct {
-	*dochandler.ResolveHandler
-}
-
-func newSampleResolveHandler(resolver dochandler.Resolver) *sampleResolveHandler {
-	return &sampleResolveHandler{
-		ResolveHandler: dochandler.NewResolveHandler(resolver),
-	}
-}
-
-// Path returns the context path
-func (h *sampleResolveHandler) Path() string {
-	return samplePath + "/{id}"
-}
-
-// Method returns the HTTP method
-func (h *sampleResolveHandler) Method() string {
-	return http.MethodGet
-}
-
-// Handler returns the handler
-func (h *sampleResolveHandler) Handler() common.HTTPRequestHandler {
-	return h.Resolve
-}
-
 func getCreateRequest() ([]byte, error) {
 	info := &helper.CreateRequestInfo{
 		OpaqueDocument: validDoc,
-		RecoveryKey:    &jws.JWK{},
+		RecoveryKey:    &jws.JWK{Kty: "EC", Crv: "P-256K", X: "PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA"},
 		MultihashCode:  sha2_256,
 	}
 	return helper.NewCreateRequest(info)
@@ -237,9 +359,14 @@ func getCreateRequest() ([]byte, error) {
 
 const validDoc = `{
 	"publicKey": [{
-		"controller": "controller",
-		"id": "#key-1",
-		"publicKeyBase58": "GY4GunSXBPBfhLCzDL7iGmP5dR3sBDCJZkkaGK8VgYQf",
-		"type": "Ed25519VerificationKey2018"
+		"id": "key-1",
+		"type": "JwsVerificationKey2020",
+		"usage": ["ops", "general"],
+		"jwk": {
+			"kty": "EC",
+			"crv": "P-256K",
+			"x": "PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA",
+			"y": "nM84jDHCMOTGTh_ZdHq4dBBdo4Z5PkEOW9jA8z8IsGc"
+		}
 	}]
 }`
@@ -0,0 +1,272 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package jcs implements the JSON Canonicalization Scheme defined in
+// RFC 8785 (JCS): object members are sorted by their UTF-16 code units,
+// numbers are serialized using ECMAScript Number::toString semantics, and
+// strings are escaped per the RFC's rules. Array order is preserved.
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Transform returns the JCS canonical serialization of the JSON document in
+// data.
+func Transform(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("jcs: invalid JSON: %w", err)
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("jcs: trailing data after JSON value")
+	}
+
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// IsCanonical reports whether data is already in JCS canonical form.
+func IsCanonical(data []byte) (bool, error) {
+	canonical, err := Transform(data)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(bytes.TrimSpace(data), canonical), nil
+}
+
+func encodeValue(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if v {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		encodeString(buf, v)
+	case []interface{}:
+		return encodeArray(buf, v)
+	case map[string]interface{}:
+		return encodeObject(buf, v)
+	default:
+		return fmt.Errorf("jcs: unsupported value type %T", value)
+	}
+
+	return nil
+}
+
+func encodeArray(buf *bytes.Buffer, arr []interface{}) error {
+	buf.WriteByte('[')
+
+	for i, v := range arr {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := encodeValue(buf, v); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte(']')
+
+	return nil
+}
+
+func encodeObject(buf *bytes.Buffer, obj map[string]interface{}) error {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+	buf.WriteByte('{')
+
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		encodeString(buf, k)
+		buf.WriteByte(':')
+
+		if err := encodeValue(buf, obj[k]); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// lessUTF16 reports whether a sorts before b when compared code unit by code
+// unit over their UTF-16 representations, as RFC 8785 section 3.2.3 requires.
+func lessUTF16(a, b string) bool {
+	au := utf16.Encode([]rune(a))
+	bu := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(au) && i < len(bu); i++ {
+		if au[i] != bu[i] {
+			return au[i] < bu[i]
+		}
+	}
+
+	return len(au) < len(bu)
+}
+
+func encodeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+				continue
+			}
+			// RFC 8785 leaves all other Unicode characters, including
+			// non-ASCII ones, unescaped in their UTF-8 form.
+			buf.WriteRune(r)
+		}
+	}
+
+	buf.WriteByte('"')
+}
+
+// encodeNumber formats n using ECMAScript Number::toString semantics, as
+// mandated by RFC 8785 section 3.2.2.3.
+func encodeNumber(buf *bytes.Buffer, n json.Number) error {
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("jcs: invalid number %q: %w", n, err)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("jcs: number %q is not representable in JSON", n)
+	}
+
+	buf.WriteString(formatES6Number(f))
+
+	return nil
+}
+
+// formatES6Number formats f the way the ECMAScript Number::toString
+// algorithm does: the shortest decimal digit string that round-trips to f,
+// placed in plain or exponential notation depending on its magnitude.
+func formatES6Number(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// strconv's shortest round-tripping form, e.g. "1.2345e+10", gives us
+	// the significant digits and decimal exponent to work from.
+	sci := strconv.FormatFloat(f, 'e', -1, 64)
+
+	mantissa, expPart, _ := cut(sci, "e")
+	digits := mantissa
+	if dot := indexByte(mantissa, '.'); dot >= 0 {
+		digits = mantissa[:dot] + mantissa[dot+1:]
+	}
+
+	exp, err := strconv.Atoi(expPart)
+	if err != nil {
+		// Unreachable: strconv.FormatFloat always produces a valid exponent.
+		panic(err)
+	}
+
+	k := len(digits)
+	n := exp + 1
+
+	switch {
+	case n >= 1 && n <= 21 && n >= k:
+		return sign + digits + zeros(n-k)
+	case n >= 1 && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case n <= 0 && n > -6:
+		return sign + "0." + zeros(-n) + digits
+	default:
+		expSign := "+"
+		expVal := n - 1
+		if expVal < 0 {
+			expSign = "-"
+			expVal = -expVal
+		}
+
+		if k == 1 {
+			return sign + digits + "e" + expSign + strconv.Itoa(expVal)
+		}
+
+		return sign + digits[:1] + "." + digits[1:] + "e" + expSign + strconv.Itoa(expVal)
+	}
+}
+
+func zeros(n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	return string(bytes.Repeat([]byte{'0'}, n))
+}
+
+func indexByte(s string, b byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := indexByte(s, sep[0]); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+
+	return s, "", false
+}
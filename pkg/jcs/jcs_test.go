@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_SortsObjectKeysByUTF16CodeUnit(t *testing.T) {
+	canonical, err := Transform([]byte(`{"b":1,"a":2,"€":3}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":2,"b":1,"€":3}`, string(canonical))
+}
+
+func TestTransform_PreservesArrayOrder(t *testing.T) {
+	canonical, err := Transform([]byte(`{"list":[3,1,2]}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"list":[3,1,2]}`, string(canonical))
+}
+
+func TestTransform_NumberFormatting(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"1", "1"},
+		{"-1", "-1"},
+		{"100", "100"},
+		{"123", "123"},
+		{"0.5", "0.5"},
+		{"0.001", "0.001"},
+		{"1e21", "1e+21"},
+		{"1e-7", "1e-7"},
+		{"1.5e10", "15000000000"},
+	}
+
+	for _, tt := range tests {
+		canonical, err := Transform([]byte(tt.in))
+		require.NoError(t, err)
+		require.Equal(t, tt.want, string(canonical), "input %s", tt.in)
+	}
+}
+
+func TestTransform_EscapesStrings(t *testing.T) {
+	canonical, err := Transform([]byte(`"line1\nline2\ttab\"quote"`))
+	require.NoError(t, err)
+	require.Equal(t, `"line1\nline2\ttab\"quote"`, string(canonical))
+}
+
+func TestTransform_RejectsInvalidJSON(t *testing.T) {
+	_, err := Transform([]byte(`{not json`))
+	require.Error(t, err)
+}
+
+func TestTransform_RejectsTrailingData(t *testing.T) {
+	_, err := Transform([]byte(`{}{}`))
+	require.Error(t, err)
+}
+
+func TestIsCanonical(t *testing.T) {
+	ok, err := IsCanonical([]byte(`{"a":1,"b":2}`))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = IsCanonical([]byte(`{"b":2,"a":1}`))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
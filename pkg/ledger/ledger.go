@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package ledger implements a minimal, fully in-memory stand-in for the
+// chain a Sidetree node anchors operation batches to. Operations queue up
+// in a pending pool until Anchor cuts them into a batch file, map file and
+// anchor file and appends a new transaction to the ledger's log, mirroring
+// the real Sidetree batch -> anchor -> map -> observer pipeline. Unlike a
+// real ledger, cutting a batch and rewinding the chain are both under the
+// caller's explicit control, so tests can drive the pipeline deterministically.
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+)
+
+// sha2_256 is the multihash code used to address content in the ledger's
+// store; it isn't tied to any namespace's protocol version.
+const sha2_256 = 18
+
+// BatchFile is the set of operations anchored together, each one encoded as
+// in the Sidetree protocol.
+type BatchFile struct {
+	Operations []string `json:"operations"`
+}
+
+// MapFile references the batch file backing an anchored set of operations.
+type MapFile struct {
+	BatchFileHash string `json:"batchFileHash"`
+}
+
+// AnchorFile is the file whose content address is recorded in the ledger's
+// transaction log; it references the map file and lists the unique suffixes
+// declared in the underlying batch.
+type AnchorFile struct {
+	MapFileHash    string   `json:"mapFileHash"`
+	UniqueSuffixes []string `json:"uniqueSuffixes"`
+}
+
+// Transaction is a single anchored entry in the ledger's transaction log.
+type Transaction struct {
+	Height        uint64 `json:"height"`
+	AnchorAddress string `json:"anchorAddress"`
+}
+
+// ErrNothingPending is returned by Anchor when the pending pool is empty.
+var ErrNothingPending = fmt.Errorf("ledger: nothing pending to anchor")
+
+// Ledger is a controllable, in-memory mock of a Sidetree anchoring chain.
+type Ledger struct {
+	mu sync.Mutex
+
+	cas        map[string][]byte
+	pending    []*batch.OperationInfo
+	txns       []Transaction
+	nextHeight uint64 // monotonically increasing; never rolled back by Rewind
+}
+
+// New returns an empty Ledger at height 0.
+func New() *Ledger {
+	return &Ledger{cas: make(map[string][]byte)}
+}
+
+// Add queues op to be included in the next anchored batch.
+func (l *Ledger) Add(op *batch.OperationInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending = append(l.pending, op)
+}
+
+// Pending returns the operations queued for the next batch, oldest first.
+func (l *Ledger) Pending() []*batch.OperationInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pending := make([]*batch.OperationInfo, len(l.pending))
+	copy(pending, l.pending)
+
+	return pending
+}
+
+// Height returns the height of the most recent transaction on the chain, or
+// 0 if nothing has been anchored yet.
+func (l *Ledger) Height() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.tipHeight()
+}
+
+// Anchor cuts the current pending pool into a batch file, map file and
+// anchor file, writes them to the ledger's content-addressable store, and
+// appends a new transaction referencing the anchor file. The pending pool
+// is emptied on success. It returns ErrNothingPending if there is nothing
+// queued.
+func (l *Ledger) Anchor() (Transaction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) == 0 {
+		return Transaction{}, ErrNothingPending
+	}
+
+	operations := make([]string, len(l.pending))
+	suffixes := make([]string, len(l.pending))
+
+	for i, op := range l.pending {
+		operations[i] = docutil.EncodeToString(op.Data)
+		suffixes[i] = op.UniqueSuffix
+	}
+
+	batchHash, err := l.write(BatchFile{Operations: operations})
+	if err != nil {
+		return Transaction{}, fmt.Errorf("ledger: writing batch file: %w", err)
+	}
+
+	mapHash, err := l.write(MapFile{BatchFileHash: batchHash})
+	if err != nil {
+		return Transaction{}, fmt.Errorf("ledger: writing map file: %w", err)
+	}
+
+	anchorHash, err := l.write(AnchorFile{MapFileHash: mapHash, UniqueSuffixes: suffixes})
+	if err != nil {
+		return Transaction{}, fmt.Errorf("ledger: writing anchor file: %w", err)
+	}
+
+	l.nextHeight++
+	txn := Transaction{Height: l.nextHeight, AnchorAddress: anchorHash}
+	l.txns = append(l.txns, txn)
+	l.pending = nil
+
+	return txn, nil
+}
+
+// Rewind discards every transaction anchored at a height greater than
+// height, simulating a chain reorganization. It does not retroactively undo
+// the effect of transactions an Observer has already applied; nor does it
+// roll back the height counter, so a transaction anchored after a rewind is
+// assigned a new, never-before-used height rather than reusing one that an
+// Observer may already have processed.
+func (l *Ledger) Rewind(height uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if height > l.tipHeight() {
+		return fmt.Errorf("ledger: cannot rewind to height %d, chain is only at height %d", height, l.tipHeight())
+	}
+
+	kept := 0
+	for _, txn := range l.txns {
+		if txn.Height > height {
+			break
+		}
+		kept++
+	}
+	l.txns = l.txns[:kept]
+
+	return nil
+}
+
+// TransactionsSince returns the transactions anchored at a height greater
+// than height, oldest first.
+func (l *Ledger) TransactionsSince(height uint64) []Transaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var txns []Transaction
+	for _, txn := range l.txns {
+		if txn.Height > height {
+			txns = append(txns, txn)
+		}
+	}
+
+	return txns
+}
+
+// tipHeight returns the height of the most recent transaction, or 0 if
+// nothing has been anchored yet. Callers must hold l.mu.
+func (l *Ledger) tipHeight() uint64 {
+	if len(l.txns) == 0 {
+		return 0
+	}
+
+	return l.txns[len(l.txns)-1].Height
+}
+
+// Read returns the content previously written to the store under address.
+func (l *Ledger) Read(address string) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	content, ok := l.cas[address]
+	if !ok {
+		return nil, fmt.Errorf("ledger: no content at address %q", address)
+	}
+
+	return content, nil
+}
+
+// write marshals v, stores it under its content address, and returns that
+// address. Callers must hold l.mu.
+func (l *Ledger) write(v interface{}) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := docutil.ComputeMultihash(sha2_256, content)
+	if err != nil {
+		return "", err
+	}
+
+	address := docutil.EncodeToString(hash)
+	l.cas[address] = content
+
+	return address, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+)
+
+func TestLedger_Anchor(t *testing.T) {
+	t.Run("fails when nothing is pending", func(t *testing.T) {
+		l := New()
+
+		_, err := l.Anchor()
+		require.Equal(t, ErrNothingPending, err)
+	})
+
+	t.Run("cuts the pending pool into a transaction and clears it", func(t *testing.T) {
+		l := New()
+		l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "abc"})
+		l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "def"})
+		require.Len(t, l.Pending(), 2)
+
+		txn, err := l.Anchor()
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), txn.Height)
+		require.NotEmpty(t, txn.AnchorAddress)
+
+		require.Empty(t, l.Pending())
+		require.Equal(t, uint64(1), l.Height())
+	})
+
+	t.Run("anchor file resolves through map file to batch file", func(t *testing.T) {
+		l := New()
+		l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "abc"})
+
+		txn, err := l.Anchor()
+		require.NoError(t, err)
+
+		anchorContent, err := l.Read(txn.AnchorAddress)
+		require.NoError(t, err)
+		require.Contains(t, string(anchorContent), "abc")
+
+		var anchorFile AnchorFile
+		require.NoError(t, json.Unmarshal(anchorContent, &anchorFile))
+
+		mapContent, err := l.Read(anchorFile.MapFileHash)
+		require.NoError(t, err)
+
+		var mapFile MapFile
+		require.NoError(t, json.Unmarshal(mapContent, &mapFile))
+
+		batchContent, err := l.Read(mapFile.BatchFileHash)
+		require.NoError(t, err)
+
+		var batchFile BatchFile
+		require.NoError(t, json.Unmarshal(batchContent, &batchFile))
+		require.Equal(t, []string{docutil.EncodeToString([]byte(`{"type":"create"}`))}, batchFile.Operations)
+	})
+}
+
+func TestLedger_TransactionsSince(t *testing.T) {
+	l := New()
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "abc"})
+	txn1, err := l.Anchor()
+	require.NoError(t, err)
+
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "def"})
+	txn2, err := l.Anchor()
+	require.NoError(t, err)
+
+	require.Equal(t, []Transaction{txn1, txn2}, l.TransactionsSince(0))
+	require.Equal(t, []Transaction{txn2}, l.TransactionsSince(1))
+	require.Empty(t, l.TransactionsSince(2))
+}
+
+func TestLedger_Rewind(t *testing.T) {
+	l := New()
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "abc"})
+	_, err := l.Anchor()
+	require.NoError(t, err)
+
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "def"})
+	_, err = l.Anchor()
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(2), l.Height())
+
+	require.NoError(t, l.Rewind(1))
+	require.Equal(t, uint64(1), l.Height())
+
+	require.Error(t, l.Rewind(5))
+}
+
+func TestLedger_Rewind_ReanchoringGetsANewHeight(t *testing.T) {
+	l := New()
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "abc"})
+	txn1, err := l.Anchor()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), txn1.Height)
+
+	require.NoError(t, l.Rewind(0))
+	require.Empty(t, l.TransactionsSince(0))
+
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"create"}`), UniqueSuffix: "def"})
+	txn2, err := l.Anchor()
+	require.NoError(t, err)
+
+	require.Greater(t, txn2.Height, txn1.Height,
+		"a transaction anchored after a rewind must not reuse a height an observer may have already processed")
+	require.Equal(t, []Transaction{txn2}, l.TransactionsSince(txn1.Height))
+}
@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package observer implements a pull-based stand-in for the component that
+// watches a Sidetree node's chain and applies newly anchored operations to
+// its document store. Unlike the real, channel-driven observer, this one
+// only advances when explicitly told to via ProcessNext, so tests can
+// control exactly when an anchored operation becomes resolvable.
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+)
+
+// namespaceDelimiter separates a namespace from the unique suffix in a
+// Sidetree DID, matching docutil.NamespaceDelimiter.
+const namespaceDelimiter = docutil.NamespaceDelimiter
+
+// Sink is the document store that anchored operations are applied to. It is
+// satisfied by any Sidetree document handler, e.g. *mocks.MockDocumentHandler.
+type Sink interface {
+	ProcessOperation(operation *batch.Operation) (*document.ResolutionResult, error)
+}
+
+// Ledger is the source of anchored transactions and the files they
+// reference. *ledger.Ledger satisfies it.
+type Ledger interface {
+	TransactionsSince(height uint64) []ledger.Transaction
+	Read(address string) ([]byte, error)
+}
+
+// Observer applies operations anchored on a Ledger to a Sink, one
+// transaction at a time, under explicit caller control. It is safe for
+// concurrent use, e.g. from multiple HTTP requests driving ProcessNext via
+// an admin endpoint.
+type Observer struct {
+	mu sync.Mutex
+
+	ledger    Ledger
+	protocol  coreprotocol.Client
+	namespace string
+	sink      Sink
+	height    uint64
+}
+
+// New returns an Observer that has not yet processed any transaction. The
+// operations in a transaction are parsed using the protocol version active
+// (per protocol) at the time ProcessNext is called, and their IDs are
+// derived by prefixing the parsed unique suffix with namespace.
+func New(source Ledger, activeProtocol coreprotocol.Client, namespace string, sink Sink) *Observer {
+	return &Observer{ledger: source, protocol: activeProtocol, namespace: namespace, sink: sink}
+}
+
+// Height returns the height of the last transaction this Observer has
+// processed.
+func (o *Observer) Height() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.height
+}
+
+// ProcessNext applies the next unprocessed transaction's operations to the
+// Sink and advances the Observer's height. It returns the number of
+// operations applied, which is 0 if the ledger has no unprocessed
+// transaction.
+//
+// Ledger heights are never reused, even across a rewind (see
+// Ledger.Rewind), so a transaction anchored after a reorg always has a
+// height greater than anything this Observer has already processed and is
+// picked up normally rather than skipped.
+//
+// Every operation in the transaction is parsed, using the protocol version
+// active at the time of the call, before any of them are applied to the
+// Sink: this keeps a parse failure (e.g. one caused by the active protocol
+// version moving on between anchoring and observing) from leaving some of
+// the transaction's operations applied and others not. If applying a
+// successfully-parsed operation to the Sink fails, the Observer still
+// advances past the transaction rather than retrying it, since the
+// operations before the failing one have already taken effect on the Sink
+// and are not safe to replay.
+func (o *Observer) ProcessNext() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	txns := o.ledger.TransactionsSince(o.height)
+	if len(txns) == 0 {
+		return 0, nil
+	}
+
+	txn := txns[0]
+
+	anchorContent, err := o.ledger.Read(txn.AnchorAddress)
+	if err != nil {
+		return 0, fmt.Errorf("observer: reading anchor file: %w", err)
+	}
+
+	var anchorFile ledger.AnchorFile
+	if err := json.Unmarshal(anchorContent, &anchorFile); err != nil {
+		return 0, fmt.Errorf("observer: decoding anchor file: %w", err)
+	}
+
+	mapContent, err := o.ledger.Read(anchorFile.MapFileHash)
+	if err != nil {
+		return 0, fmt.Errorf("observer: reading map file: %w", err)
+	}
+
+	var mapFile ledger.MapFile
+	if err := json.Unmarshal(mapContent, &mapFile); err != nil {
+		return 0, fmt.Errorf("observer: decoding map file: %w", err)
+	}
+
+	batchContent, err := o.ledger.Read(mapFile.BatchFileHash)
+	if err != nil {
+		return 0, fmt.Errorf("observer: reading batch file: %w", err)
+	}
+
+	var batchFile ledger.BatchFile
+	if err := json.Unmarshal(batchContent, &batchFile); err != nil {
+		return 0, fmt.Errorf("observer: decoding batch file: %w", err)
+	}
+
+	ops := make([]*batch.Operation, len(batchFile.Operations))
+	for i, encoded := range batchFile.Operations {
+		op, err := o.parseOperation(encoded)
+		if err != nil {
+			return 0, fmt.Errorf("observer: parsing anchored operation: %w", err)
+		}
+
+		ops[i] = op
+	}
+
+	applied := 0
+	for _, op := range ops {
+		if _, err := o.sink.ProcessOperation(op); err != nil {
+			o.height = txn.Height
+			return applied, fmt.Errorf("observer: applying anchored operation: %w", err)
+		}
+
+		applied++
+	}
+
+	o.height = txn.Height
+
+	return applied, nil
+}
+
+func (o *Observer) parseOperation(encoded string) (*batch.Operation, error) {
+	request, err := docutil.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema struct {
+		Operation model.OperationType `json:"type"`
+	}
+	if err := json.Unmarshal(request, &schema); err != nil {
+		return nil, err
+	}
+
+	protocol := o.protocol.Current()
+
+	var op *batch.Operation
+	var parseErr error
+
+	switch schema.Operation {
+	case model.OperationTypeCreate:
+		op, parseErr = operation.ParseCreateOperation(request, protocol)
+	case model.OperationTypeUpdate:
+		op, parseErr = operation.ParseUpdateOperation(request, protocol)
+	case model.OperationTypeDeactivate:
+		op, parseErr = operation.ParseDeactivateOperation(request, protocol)
+	case model.OperationTypeRecover:
+		op, parseErr = operation.ParseRecoverOperation(request, protocol)
+	default:
+		return nil, fmt.Errorf("operation type [%s] not implemented", schema.Operation)
+	}
+
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	op.ID = o.namespace + namespaceDelimiter + op.UniqueSuffix
+
+	return op, nil
+}
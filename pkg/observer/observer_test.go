@@ -0,0 +1,274 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/Moopli/sidetree-mock/pkg/ledger"
+	"github.com/Moopli/sidetree-mock/pkg/protocol"
+)
+
+const (
+	namespace = "did:sidetree"
+	sha2_256  = 18
+)
+
+func TestObserver_ProcessNext(t *testing.T) {
+	l := ledger.New()
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sink := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(registry)
+
+	o := New(l, registry, namespace, sink)
+
+	t.Run("does nothing when the ledger has no new transaction", func(t *testing.T) {
+		applied, err := o.ProcessNext()
+		require.NoError(t, err)
+		require.Equal(t, 0, applied)
+	})
+
+	req, suffix := createRequest(t)
+	l.Add(&batch.OperationInfo{Data: req, UniqueSuffix: suffix})
+
+	t.Run("does not apply an operation that is only pending, not yet anchored", func(t *testing.T) {
+		applied, err := o.ProcessNext()
+		require.NoError(t, err)
+		require.Equal(t, 0, applied)
+
+		_, err = sink.ResolveDocument(namespace + ":" + suffix)
+		require.Error(t, err)
+	})
+
+	_, err := l.Anchor()
+	require.NoError(t, err)
+
+	t.Run("applies the anchored operation to the sink", func(t *testing.T) {
+		applied, err := o.ProcessNext()
+		require.NoError(t, err)
+		require.Equal(t, 1, applied)
+		require.Equal(t, uint64(1), o.Height())
+
+		result, err := sink.ResolveDocument(namespace + ":" + suffix)
+		require.NoError(t, err)
+		require.Equal(t, namespace+":"+suffix, result.Document["id"])
+	})
+
+	t.Run("does not reapply an already-processed transaction", func(t *testing.T) {
+		applied, err := o.ProcessNext()
+		require.NoError(t, err)
+		require.Equal(t, 0, applied)
+	})
+}
+
+// TestObserver_ProcessNext_Reorg verifies that an Observer still picks up a
+// transaction anchored to replace one it had already processed before a
+// rewind; see Ledger.Rewind.
+func TestObserver_ProcessNext_Reorg(t *testing.T) {
+	l := ledger.New()
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sink := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(registry)
+	o := New(l, registry, namespace, sink)
+
+	req1, suffix1 := createRequestWithKeyID(t, "key-1")
+	l.Add(&batch.OperationInfo{Data: req1, UniqueSuffix: suffix1})
+	_, err := l.Anchor()
+	require.NoError(t, err)
+
+	applied, err := o.ProcessNext()
+	require.NoError(t, err)
+	require.Equal(t, 1, applied)
+	require.Equal(t, uint64(1), o.Height())
+
+	// Simulate a reorg: the chain is rewound, discarding the transaction the
+	// Observer already processed, and a different operation is anchored in
+	// its place.
+	require.NoError(t, l.Rewind(0))
+	require.Equal(t, uint64(0), l.Height())
+
+	req2, suffix2 := createRequestWithKeyID(t, "key-2")
+	l.Add(&batch.OperationInfo{Data: req2, UniqueSuffix: suffix2})
+	txn, err := l.Anchor()
+	require.NoError(t, err)
+	require.Greater(t, txn.Height, o.Height(), "a transaction anchored after a rewind must get a height the Observer hasn't already seen")
+
+	applied, err = o.ProcessNext()
+	require.NoError(t, err)
+	require.Equal(t, 1, applied, "the transaction anchored after the rewind must still be processed")
+
+	_, err = sink.ResolveDocument(namespace + ":" + suffix2)
+	require.NoError(t, err)
+}
+
+// TestObserver_ProcessNext_AtomicParsing verifies that a transaction whose
+// operations fail to parse (e.g. because the active protocol version moved
+// on between anchoring and observing) has none of its operations applied,
+// rather than applying the operations that happened to parse before the
+// failing one.
+func TestObserver_ProcessNext_AtomicParsing(t *testing.T) {
+	l := ledger.New()
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sink := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(registry)
+	o := New(l, registry, namespace, sink)
+
+	req, suffix := createRequestWithKeyID(t, "key-1")
+	l.Add(&batch.OperationInfo{Data: req, UniqueSuffix: suffix})
+	l.Add(&batch.OperationInfo{Data: []byte(`{"type":"bogus"}`), UniqueSuffix: "unparseable"})
+
+	_, err := l.Anchor()
+	require.NoError(t, err)
+
+	applied, err := o.ProcessNext()
+	require.Error(t, err)
+	require.Equal(t, 0, applied)
+	require.Equal(t, uint64(0), o.Height(), "a transaction that fails to parse must not advance the observer's height")
+
+	_, err = sink.ResolveDocument(namespace + ":" + suffix)
+	require.Error(t, err, "the operation preceding the unparseable one must not have been applied")
+}
+
+// TestObserver_ProcessNext_AdvancesPastFailedTransaction verifies that, once
+// some of a transaction's operations have been applied to the sink, a
+// later failure within that same transaction advances the Observer past it
+// instead of retrying it on the next call, which would reapply the
+// already-applied (and not necessarily idempotent) operations.
+func TestObserver_ProcessNext_AdvancesPastFailedTransaction(t *testing.T) {
+	l := ledger.New()
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sink := &failingSink{failAfter: 1}
+	o := New(l, registry, namespace, sink)
+
+	req1, suffix1 := createRequestWithKeyID(t, "key-1")
+	req2, suffix2 := createRequestWithKeyID(t, "key-2")
+	l.Add(&batch.OperationInfo{Data: req1, UniqueSuffix: suffix1})
+	l.Add(&batch.OperationInfo{Data: req2, UniqueSuffix: suffix2})
+
+	txn, err := l.Anchor()
+	require.NoError(t, err)
+
+	applied, err := o.ProcessNext()
+	require.Error(t, err)
+	require.Equal(t, 1, applied, "the operation before the failing one was already applied to the sink")
+	require.Equal(t, txn.Height, o.Height(),
+		"the Observer must advance past the transaction rather than retry it and reapply the operation that already succeeded")
+
+	applied, err = o.ProcessNext()
+	require.NoError(t, err)
+	require.Equal(t, 0, applied, "a transaction the Observer has advanced past must not be retried")
+}
+
+// failingSink applies the first failAfter operations it's given successfully
+// and fails every one after that.
+type failingSink struct {
+	failAfter int
+	calls     int
+}
+
+func (s *failingSink) ProcessOperation(op *batch.Operation) (*document.ResolutionResult, error) {
+	s.calls++
+	if s.calls > s.failAfter {
+		return nil, fmt.Errorf("failingSink: forced failure on call %d", s.calls)
+	}
+
+	return &document.ResolutionResult{}, nil
+}
+
+// TestObserver_ProcessNext_ConcurrentCallsDoNotRace verifies that concurrent
+// ProcessNext calls (e.g. from concurrent POST /admin/observe requests) are
+// safe, each anchored transaction is applied exactly once, and none are
+// skipped.
+func TestObserver_ProcessNext_ConcurrentCallsDoNotRace(t *testing.T) {
+	const transactions = 10
+
+	l := ledger.New()
+	registry := protocol.NewRegistry(protocol.Version{
+		Name:     "0.1",
+		Protocol: coreprotocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+	})
+	sink := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(registry)
+	o := New(l, registry, namespace, sink)
+
+	for i := 0; i < transactions; i++ {
+		req, suffix := createRequestWithKeyID(t, fmt.Sprintf("key-%d", i))
+		l.Add(&batch.OperationInfo{Data: req, UniqueSuffix: suffix})
+		_, err := l.Anchor()
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	var totalApplied int32
+	var mu sync.Mutex
+
+	for i := 0; i < transactions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			applied, err := o.ProcessNext()
+			require.NoError(t, err)
+
+			mu.Lock()
+			totalApplied += int32(applied)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, transactions, totalApplied, "every anchored transaction must be applied exactly once")
+	require.Equal(t, uint64(transactions), o.Height())
+}
+
+func createRequest(t *testing.T) (request []byte, uniqueSuffix string) {
+	return createRequestWithKeyID(t, "key-1")
+}
+
+func createRequestWithKeyID(t *testing.T, keyID string) (request []byte, uniqueSuffix string) {
+	doc := fmt.Sprintf(`{"publicKey":[{"id":%q,"type":"JwsVerificationKey2020","usage":["ops","general"],`+
+		`"jwk":{"kty":"EC","crv":"P-256K","x":"PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA",`+
+		`"y":"nM84jDHCMOTGTh_ZdHq4dBBdo4Z5PkEOW9jA8z8IsGc"}}]}`, keyID)
+
+	info := &helper.CreateRequestInfo{
+		OpaqueDocument: doc,
+		RecoveryKey:    &jws.JWK{Kty: "EC", Crv: "P-256K", X: "PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA"},
+		MultihashCode:  sha2_256,
+	}
+
+	req, err := helper.NewCreateRequest(info)
+	require.NoError(t, err)
+
+	var createReq model.CreateRequest
+	require.NoError(t, json.Unmarshal(req, &createReq))
+
+	suffix, err := docutil.CalculateUniqueSuffix(createReq.SuffixData, sha2_256)
+	require.NoError(t, err)
+
+	return req, suffix
+}
@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package protocol provides a protocol.Client implementation backed by an
+// ordered set of protocol versions, so that the mock node can simulate
+// rolling Sidetree protocol upgrades against a single running server.
+package protocol
+
+import (
+	"sort"
+	"sync"
+
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+// Version pairs a human-readable name with the Sidetree protocol parameters
+// that take effect once the mock ledger reaches GenesisBlockHeight.
+type Version struct {
+	Name               string                `json:"name"`
+	GenesisBlockHeight uint                  `json:"genesisBlockHeight"`
+	Protocol           coreprotocol.Protocol `json:"protocol"`
+}
+
+// Registry is a coreprotocol.Client backed by an ordered set of protocol
+// versions, each taking effect at a configurable mock block height. Tests
+// drive rolling protocol upgrades by calling SetBlockHeight.
+type Registry struct {
+	mu       sync.RWMutex
+	versions []Version // sorted ascending by GenesisBlockHeight
+	height   uint
+}
+
+// NewRegistry returns a Registry seeded with versions, which need not be
+// supplied in genesis-height order. At least one version is required.
+func NewRegistry(versions ...Version) *Registry {
+	if len(versions) == 0 {
+		panic("protocol: registry requires at least one version")
+	}
+
+	sorted := make([]Version, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GenesisBlockHeight < sorted[j].GenesisBlockHeight
+	})
+
+	return &Registry{versions: sorted}
+}
+
+// SetBlockHeight sets the mock node's current block height, changing which
+// version Current and CurrentVersion return.
+func (r *Registry) SetBlockHeight(height uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.height = height
+}
+
+// BlockHeight returns the mock node's current block height.
+func (r *Registry) BlockHeight() uint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.height
+}
+
+// Current returns the protocol parameters in effect at the current block
+// height. It satisfies coreprotocol.Client.
+func (r *Registry) Current() coreprotocol.Protocol {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.active().Protocol
+}
+
+// CurrentVersion returns the full version descriptor (name, genesis height,
+// and protocol parameters) in effect at the current block height.
+func (r *Registry) CurrentVersion() Version {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.active()
+}
+
+// Versions returns all versions known to the registry, oldest first.
+func (r *Registry) Versions() []Version {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]Version, len(r.versions))
+	copy(versions, r.versions)
+
+	return versions
+}
+
+// active returns the last version whose GenesisBlockHeight is <= the current
+// height, falling back to the oldest version if none has taken effect yet.
+func (r *Registry) active() Version {
+	active := r.versions[0]
+	for _, v := range r.versions[1:] {
+		if v.GenesisBlockHeight > r.height {
+			break
+		}
+		active = v
+	}
+
+	return active
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coreprotocol "github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+func TestRegistry_CurrentAdvancesWithBlockHeight(t *testing.T) {
+	v0 := Version{Name: "0.1", GenesisBlockHeight: 0, Protocol: coreprotocol.Protocol{MaxOperationsPerBatch: 1}}
+	v1 := Version{Name: "0.2", GenesisBlockHeight: 100, Protocol: coreprotocol.Protocol{MaxOperationsPerBatch: 2}}
+
+	// pass out of genesis-height order to verify the registry sorts them
+	r := NewRegistry(v1, v0)
+
+	require.Equal(t, v0.Protocol, r.Current())
+	require.Equal(t, v0, r.CurrentVersion())
+
+	r.SetBlockHeight(99)
+	require.Equal(t, v0.Protocol, r.Current())
+
+	r.SetBlockHeight(100)
+	require.Equal(t, v1.Protocol, r.Current())
+	require.Equal(t, v1, r.CurrentVersion())
+
+	r.SetBlockHeight(1000)
+	require.Equal(t, v1.Protocol, r.Current())
+
+	require.Equal(t, []Version{v0, v1}, r.Versions())
+}
+
+func TestRegistry_RequiresAtLeastOneVersion(t *testing.T) {
+	require.Panics(t, func() {
+		NewRegistry()
+	})
+}
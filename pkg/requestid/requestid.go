@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package requestid propagates a request-scoped trace ID across a request's
+// handler chain, into structured logs, and (eventually) into outbound calls,
+// so that a single inbound request can be correlated end-to-end.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+)
+
+// Header is the HTTP header carrying the request ID, both inbound (if the
+// caller supplies one) and outbound (echoed back on every response).
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id, retrievable with FromContext.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" if none is set.
+// Outbound calls made on behalf of a request (e.g. future webhook
+// integrations) should set Header to this value so the downstream service's
+// logs can be correlated with the request that triggered them.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Middleware reads the X-Request-ID header from the incoming request, or
+// generates one if the caller didn't supply one, stores it on the request
+// context, and echoes it back on the response header so that callers and
+// server logs can correlate the request end-to-end.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(Header)
+		if id == "" {
+			id = uuid.Must(uuid.NewV4()).String()
+		}
+
+		rw.Header().Set(Header, id)
+		next.ServeHTTP(rw, req.WithContext(NewContext(req.Context(), id)))
+	})
+}
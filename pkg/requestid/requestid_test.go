@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_GeneratesIDWhenAbsent(t *testing.T) {
+	var gotID string
+
+	handler := Middleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotID = FromContext(req.Context())
+	}))
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.NotEmpty(t, gotID)
+	require.Equal(t, gotID, rw.Header().Get(Header))
+}
+
+func TestMiddleware_PreservesCallerSuppliedID(t *testing.T) {
+	var gotID string
+
+	handler := Middleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotID = FromContext(req.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(Header, "caller-supplied-id")
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	require.Equal(t, "caller-supplied-id", gotID)
+	require.Equal(t, "caller-supplied-id", rw.Header().Get(Header))
+}
+
+func TestFromContext_EmptyWhenNotSet(t *testing.T) {
+	require.Empty(t, FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}